@@ -2,7 +2,9 @@ package main
 
 import (
 	"database/sql"
+	"flag"
 	"fmt"
+	"hash/fnv"
 	"log"
 	"os"
 	"strings"
@@ -10,41 +12,123 @@ import (
 	"time"
 
 	_ "github.com/denisenkom/go-mssqldb"
-	_ "github.com/lib/pq"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/robfig/cron/v3"
 )
 
 // --- CONFIGURACION OPTIMA ---
 const (
 	MaxWorkers = 30    // 30 es el punto dulce para tu hardware
-	BatchSize  = 25000 
+	BatchSize  = 25000
 	MaxRetries = 10
+
+	ChunkThreshold = 500000 // con mas filas que esto, partimos la tabla en chunks
+	ChunkWorkers   = 8      // goroutines secundarias por tabla grande
+
+	ReconcileProgressEvery = 1000000 // cada cuantas filas loguear progreso del checksum de reconciliacion
 )
 
 var SourceDBs = []string{"vin", "exportacion", "exportasis", "siscon", "BD_FACTURA", "declaracion"}
 
+var (
+	incrementalFlag = flag.Bool("incremental", false, "Activa modo incremental (CDC) usando MSSQL Change Tracking")
+	cronSpec        = flag.String("cron", "", "Cron spec para correr como daemon (ej: */5 * * * *). Vacio = corre una vez")
+	resumeFlag      = flag.Bool("resume", false, "Omite tablas cuyo ultimo run ya llego a fks_applied")
+	retryFailedFlag = flag.Bool("retry-failed", false, "Solo reprocesa tablas cuyo ultimo run quedo con error")
+	sinceFlag       = flag.String("since", "", "Con --resume, solo considera runs posteriores a este timestamp (RFC3339)")
+	runIDFlag       = flag.String("run-id", "", "Run ID a usar para el tracking de migration_runs. Vacio = se genera uno nuevo")
+
+	reconcileTolerance         = flag.Int64("reconcile-tolerance", 0, "Diferencia maxima tolerada entre src_rows y dst_rows antes de marcar mismatch")
+	reconcileChecksumThreshold = flag.Int64("reconcile-checksum-threshold", ChunkThreshold, "Tablas con mas filas que esto reconcilian solo por row count: el checksum columna por columna (un segundo full scan por motor) se omite por costo. 0 desactiva el umbral y siempre calcula el checksum")
+
+	targetFlag = flag.String("target", "postgres", "Motor destino: postgres | mysql | sqlite")
+)
+
 type ForeignKeySQL struct {
+	SourceDB       string
+	TableName      string
 	ConstraintName string
 	SQL            string
 }
 
 func main() {
+	flag.Parse()
 	log.Println("[INFO] Iniciando Migracion FINAL (Strict Types + TrimSpaces + High Performance)...")
 
-	required := []string{"PG_HOST", "MSSQL_HOST", "MSSQL_PASS"}
+	required := []string{"MSSQL_HOST", "MSSQL_PASS"}
 	for _, v := range required {
 		if os.Getenv(v) == "" {
 			log.Fatalf("[FATAL] Falta variable de entorno: %s", v)
 		}
 	}
 
-	// Connection String sin options raros para evitar error de driver
-	pgConnStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable binary_parameters=yes",
-		os.Getenv("PG_HOST"), os.Getenv("PG_PORT"), os.Getenv("PG_USER"), os.Getenv("PG_PASS"), os.Getenv("PG_DB"))
+	dialect, driverName, err := newTargetDialect(*targetFlag)
+	if err != nil {
+		log.Fatalf("[FATAL] %v", err)
+	}
+
+	// Connection String sin options raros para evitar error de driver. Para
+	// target=postgres se arma desde las variables PG_* de siempre si no se
+	// definio TARGET_DSN explicitamente; mysql y sqlite requieren TARGET_DSN.
+	targetConnStr := os.Getenv("TARGET_DSN")
+	if targetConnStr == "" {
+		if dialect.Name() != "postgres" {
+			log.Fatalf("[FATAL] Falta variable de entorno: TARGET_DSN (requerida para --target=%s)", dialect.Name())
+		}
+		if os.Getenv("PG_HOST") == "" {
+			log.Fatalf("[FATAL] Falta variable de entorno: PG_HOST (o definir TARGET_DSN)")
+		}
+		targetConnStr = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable binary_parameters=yes",
+			os.Getenv("PG_HOST"), os.Getenv("PG_PORT"), os.Getenv("PG_USER"), os.Getenv("PG_PASS"), os.Getenv("PG_DB"))
+	}
+
+	if dialect.Name() != "postgres" && (*incrementalFlag || *resumeFlag || *retryFailedFlag) {
+		log.Printf("[WARN] --incremental/--resume/--retry-failed dependen de las tablas de control svcs_meta en Postgres; se ignoran para --target=%s", dialect.Name())
+	}
+
+	runAll := func() int {
+		runID := *runIDFlag
+		if runID == "" {
+			runID = fmt.Sprintf("run_%d", time.Now().UnixNano())
+		}
+		log.Printf("[INFO] run_id=%s target=%s", runID, dialect.Name())
+		mismatches := 0
+		for _, dbName := range SourceDBs {
+			mismatches += processDatabase(dbName, targetConnStr, driverName, runID, dialect)
+		}
+		return mismatches
+	}
+
+	if *cronSpec == "" {
+		mismatches := runAll()
+		log.Println("[INFO] Migracion Completa Finalizada.")
+		if mismatches > 0 {
+			log.Printf("[ERROR] %d tablas con mismatch en la reconciliacion", mismatches)
+			os.Exit(1)
+		}
+		return
+	}
 
-	for _, dbName := range SourceDBs {
-		processDatabase(dbName, pgConnStr)
+	log.Printf("[INFO] Modo daemon activo, cron spec %q", *cronSpec)
+	var mu sync.Mutex
+	c := cron.New()
+	_, err = c.AddFunc(*cronSpec, func() {
+		if !mu.TryLock() {
+			log.Println("[WARN] El tick anterior todavia esta corriendo, se salta este tick")
+			return
+		}
+		defer mu.Unlock()
+		if mismatches := runAll(); mismatches > 0 {
+			log.Printf("[ERROR] %d tablas con mismatch en la reconciliacion de este tick", mismatches)
+		}
+	})
+	if err != nil {
+		log.Fatalf("[FATAL] Cron spec invalido: %v", err)
 	}
-	log.Println("[INFO] Migracion Completa Finalizada.")
+	c.Start()
+	select {}
 }
 
 func isIgnoredTable(tableName string) bool {
@@ -95,26 +179,472 @@ func getPostgresType(mssqlType string, precision, scale int64) string {
 	}
 }
 
-func processDatabase(dbName, pgConnStr string) {
+// coerceValue aplica las mismas conversiones que usaba migrateTable fila por
+// fila (bit->bool, uniqueidentifier->UUID, limpieza de \x00, passthrough de
+// BYTEA) para que el modo incremental pueda reusarlas.
+func coerceValue(v interface{}, mssqlType string) interface{} {
+	if v == nil {
+		return nil
+	}
+	typeName := strings.ToUpper(mssqlType)
+	switch t := v.(type) {
+	case []byte:
+		if typeName == "BIT" || typeName == "BOOLEAN" {
+			if len(t) > 0 && t[0] == 1 { return true }
+			return false
+		} else if typeName == "UNIQUEIDENTIFIER" {
+			if len(t) == 16 {
+				return fmt.Sprintf("%x-%x-%x-%x-%x", t[0:4], t[4:6], t[6:8], t[8:10], t[10:])
+			}
+			return nil
+		} else if strings.Contains(typeName, "BINARY") || strings.Contains(typeName, "IMAGE") {
+			return t
+		}
+		return strings.ReplaceAll(string(t), "\x00", "")
+	case string:
+		return strings.ReplaceAll(t, "\x00", "")
+	case bool:
+		return t
+	default:
+		return v
+	}
+}
+
+func isUnsupportedCopyTypeErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unsupported") || strings.Contains(msg, "copyin") || strings.Contains(msg, "invalid byte sequence")
+}
+
+// --- TARGET DIALECT (postgres / mysql / sqlite) ---
+
+// BulkLoader abstrae el mecanismo de carga masiva de cada motor destino
+// (COPY binario en Postgres, INSERT multi-fila en MySQL, INSERT preparado
+// en SQLite). Exec recibe una fila ya coercionada; Flush fuerza el envio
+// de lo que este bufferizado (no-op si el motor no buffer); Close libera
+// el statement/stream subyacente.
+type BulkLoader interface {
+	Exec(vals ...interface{}) error
+	Flush() error
+	Close() error
+}
+
+// TargetDialect encapsula todo lo que migrateTable necesita saber del motor
+// destino: mapeo de tipos, quoting de identificadores, placeholders,
+// mecanismo de carga masiva y los DDL de PRIMARY KEY / FOREIGN KEY. Nace de
+// sacarle a migrateTable el hardcodeo a Postgres (getPostgresType, el
+// builder de CREATE TABLE, "$1,$2,...", ADD CONSTRAINT PRIMARY KEY y SET
+// synchronous_commit) para poder migrar tambien a MySQL y SQLite.
+//
+// El modo --incremental, --resume/--retry-failed y la reconciliacion post-
+// carga siguen siendo exclusivos de Postgres: dependen de las tablas de
+// control svcs_meta.* y de funciones especificas (ON CONFLICT, hashtext),
+// asi que solo se activan cuando dialect.Name() == "postgres".
+type TargetDialect interface {
+	Name() string
+	MapType(mssqlType string, precision, scale int64) string
+	Quote(ident string) string
+	Qualify(schema, table string) string
+	Placeholder(i int) string
+	BulkLoader(tx *sql.Tx, schema, table string, cols []string) (BulkLoader, error)
+	TuneSession(tx *sql.Tx) error
+	AddPK(schema, table string, pkCols []string) string
+	AddFK(schema, table, fkName, col, refSchema, refTable, refCol string) string
+}
+
+// newTargetDialect resuelve el --target pedido por el usuario a su
+// implementacion y al nombre de driver de database/sql correspondiente.
+func newTargetDialect(target string) (TargetDialect, string, error) {
+	switch target {
+	case "postgres", "":
+		return postgresDialect{}, "postgres", nil
+	case "mysql":
+		return mysqlDialect{}, "mysql", nil
+	case "sqlite":
+		return sqliteDialect{}, "sqlite3", nil
+	default:
+		return nil, "", fmt.Errorf("target desconocido %q (valores validos: postgres, mysql, sqlite)", target)
+	}
+}
+
+// --- postgres: comportamiento historico, COPY FROM STDIN via lib/pq ---
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+func (postgresDialect) MapType(mssqlType string, precision, scale int64) string {
+	return getPostgresType(mssqlType, precision, scale)
+}
+
+func (postgresDialect) Quote(ident string) string {
+	return fmt.Sprintf(`"%s"`, ident)
+}
+
+func (postgresDialect) Qualify(schema, table string) string {
+	return fmt.Sprintf(`"%s"."%s"`, schema, table)
+}
+
+func (postgresDialect) Placeholder(i int) string {
+	return fmt.Sprintf("$%d", i+1)
+}
+
+func (postgresDialect) TuneSession(tx *sql.Tx) error {
+	_, err := tx.Exec("SET synchronous_commit TO OFF")
+	return err
+}
+
+func (d postgresDialect) AddPK(schema, table string, pkCols []string) string {
+	pkName := fmt.Sprintf("pk_%s_%s", schema, table)
+	if len(pkName) > 63 {
+		pkName = pkName[:63]
+	}
+	quoted := make([]string, len(pkCols))
+	for i, c := range pkCols {
+		quoted[i] = fmt.Sprintf(`"%s"`, c)
+	}
+	return fmt.Sprintf(`ALTER TABLE %s ADD CONSTRAINT "%s" PRIMARY KEY (%s)`, d.Qualify(schema, table), pkName, strings.Join(quoted, ","))
+}
+
+func (d postgresDialect) AddFK(schema, table, fkName, col, refSchema, refTable, refCol string) string {
+	return fmt.Sprintf(`ALTER TABLE %s ADD CONSTRAINT "%s" FOREIGN KEY ("%s") REFERENCES %s ("%s")`,
+		d.Qualify(schema, table), fkName, col, d.Qualify(refSchema, refTable), refCol)
+}
+
+func (d postgresDialect) BulkLoader(tx *sql.Tx, schema, table string, cols []string) (BulkLoader, error) {
+	placeholders := make([]string, len(cols))
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	insertQ := fmt.Sprintf(`INSERT INTO %s VALUES (%s)`, d.Qualify(schema, table), strings.Join(placeholders, ","))
+
+	stmt, err := tx.Prepare(pq.CopyInSchema(schema, table, cols...))
+	if err != nil {
+		return nil, err
+	}
+	return &postgresBulkLoader{tx: tx, stmt: stmt, useCopy: true, insertQ: insertQ, table: table}, nil
+}
+
+// postgresBulkLoader arranca siempre en modo COPY y cae a INSERT si el
+// driver reporta un tipo no soportado por el protocolo binario (tablas muy
+// anchas o con BYTEA). pending guarda las filas ya Exec'd en el COPY actual
+// para poder reinyectarlas via INSERT sin perderlas al abandonar el stream.
+type postgresBulkLoader struct {
+	tx      *sql.Tx
+	stmt    *sql.Stmt
+	useCopy bool
+	insertQ string
+	table   string
+	pending [][]interface{}
+}
+
+func (l *postgresBulkLoader) Exec(vals ...interface{}) error {
+	if _, err := l.stmt.Exec(vals...); err != nil {
+		if l.useCopy && isUnsupportedCopyTypeErr(err) {
+			return l.fallbackToInsert(vals)
+		}
+		return err
+	}
+	if l.useCopy {
+		l.pending = append(l.pending, append([]interface{}(nil), vals...))
+	}
+	return nil
+}
+
+// fallbackToInsert conmuta el loader a INSERT preparado y reinyecta las filas
+// que ya se habian mandado al stream de COPY (guardadas en l.pending). Si
+// lastVals no es nil tambien la agrega (caso: el error salio del Exec() de
+// una fila puntual, no del flush final).
+func (l *postgresBulkLoader) fallbackToInsert(lastVals []interface{}) error {
+	log.Printf("[WARN] %s: COPY no soporta un tipo de esta tabla, cayendo a INSERT", l.table)
+	l.stmt.Close()
+	l.useCopy = false
+	stmt, err := l.tx.Prepare(l.insertQ)
+	if err != nil {
+		return err
+	}
+	l.stmt = stmt
+	for _, row := range l.pending {
+		l.stmt.Exec(row...)
+	}
+	l.pending = nil
+	if lastVals == nil {
+		return nil
+	}
+	_, err = l.stmt.Exec(lastVals...)
+	return err
+}
+
+// Flush cierra el stream de COPY con el Exec() final sin argumentos. lib/pq
+// solo reporta los errores de tipo no soportado aca (el buffer se valida
+// recien al cerrar el stream), asi que hay que tratarlo igual que un error
+// de fila: si es de tipo no soportado, reinyectar el batch pendiente via
+// INSERT; si no, propagar el error para que el caller decida (rollback en
+// vez de commitear un batch que en realidad fallo).
+func (l *postgresBulkLoader) Flush() error {
+	if !l.useCopy {
+		return nil
+	}
+	_, err := l.stmt.Exec()
+	if err != nil {
+		if isUnsupportedCopyTypeErr(err) {
+			return l.fallbackToInsert(nil)
+		}
+		return err
+	}
+	l.pending = nil
+	return nil
+}
+
+func (l *postgresBulkLoader) Close() error {
+	return l.stmt.Close()
+}
+
+// --- mysql: INSERT multi-fila bufferizado ---
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) MapType(mssqlType string, precision, scale int64) string {
+	t := strings.ToUpper(mssqlType)
+	switch t {
+	case "TINYINT":
+		return "TINYINT UNSIGNED"
+	case "SMALLINT":
+		return "SMALLINT"
+	case "INT", "INTEGER":
+		return "INT"
+	case "BIGINT":
+		return "BIGINT"
+	case "BIT":
+		return "BOOLEAN"
+	case "REAL":
+		return "FLOAT"
+	case "FLOAT":
+		return "DOUBLE"
+	case "DECIMAL", "NUMERIC", "MONEY", "SMALLMONEY":
+		if precision > 0 {
+			return fmt.Sprintf("DECIMAL(%d,%d)", precision, scale)
+		}
+		return "DECIMAL(38,9)"
+	case "DATE":
+		return "DATE"
+	case "DATETIME", "DATETIME2", "SMALLDATETIME":
+		return "DATETIME"
+	case "TIME":
+		return "TIME"
+	case "CHAR", "NCHAR", "VARCHAR", "NVARCHAR", "TEXT", "NTEXT", "SYSNAME":
+		return "TEXT"
+	case "BINARY", "VARBINARY", "IMAGE", "TIMESTAMP_SQL":
+		return "BLOB"
+	case "UNIQUEIDENTIFIER":
+		return "CHAR(36)"
+	default:
+		return "TEXT"
+	}
+}
+
+func (mysqlDialect) Quote(ident string) string {
+	return fmt.Sprintf("`%s`", ident)
+}
+
+func (mysqlDialect) Qualify(schema, table string) string {
+	return fmt.Sprintf("`%s`.`%s`", schema, table)
+}
+
+func (mysqlDialect) Placeholder(i int) string { return "?" }
+
+func (mysqlDialect) TuneSession(tx *sql.Tx) error {
+	_, err := tx.Exec("SET unique_checks = 0, foreign_key_checks = 0")
+	return err
+}
+
+func (d mysqlDialect) AddPK(schema, table string, pkCols []string) string {
+	quoted := make([]string, len(pkCols))
+	for i, c := range pkCols {
+		quoted[i] = fmt.Sprintf("`%s`", c)
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s)", d.Qualify(schema, table), strings.Join(quoted, ","))
+}
+
+func (d mysqlDialect) AddFK(schema, table, fkName, col, refSchema, refTable, refCol string) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT `%s` FOREIGN KEY (`%s`) REFERENCES %s (`%s`)",
+		d.Qualify(schema, table), fkName, col, d.Qualify(refSchema, refTable), refCol)
+}
+
+const mysqlBulkLoaderMaxRows = 500
+
+func (d mysqlDialect) BulkLoader(tx *sql.Tx, schema, table string, cols []string) (BulkLoader, error) {
+	return &mysqlBulkLoader{tx: tx, qualified: d.Qualify(schema, table), numCols: len(cols)}, nil
+}
+
+// mysqlBulkLoader agrupa filas en un unico INSERT multi-fila
+// (INSERT INTO t VALUES (...),(...),...), mucho mas rapido que una fila por
+// round-trip y sin depender de que el servidor/driver tengan habilitado
+// LOCAL INFILE.
+type mysqlBulkLoader struct {
+	tx        *sql.Tx
+	qualified string
+	numCols   int
+	buf       [][]interface{}
+}
+
+func (l *mysqlBulkLoader) Exec(vals ...interface{}) error {
+	l.buf = append(l.buf, append([]interface{}(nil), vals...))
+	if len(l.buf) >= mysqlBulkLoaderMaxRows {
+		return l.Flush()
+	}
+	return nil
+}
+
+func (l *mysqlBulkLoader) Flush() error {
+	if len(l.buf) == 0 {
+		return nil
+	}
+	rowPH := "(" + strings.Repeat("?,", l.numCols-1) + "?)"
+	rowsSQL := make([]string, len(l.buf))
+	args := make([]interface{}, 0, len(l.buf)*l.numCols)
+	for i, row := range l.buf {
+		rowsSQL[i] = rowPH
+		args = append(args, row...)
+	}
+	q := fmt.Sprintf("INSERT INTO %s VALUES %s", l.qualified, strings.Join(rowsSQL, ","))
+	_, err := l.tx.Exec(q, args...)
+	l.buf = l.buf[:0]
+	return err
+}
+
+func (l *mysqlBulkLoader) Close() error {
+	return l.Flush()
+}
+
+// --- sqlite: single-writer, WAL pragma, INSERT preparado ---
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite" }
+
+func (sqliteDialect) MapType(mssqlType string, precision, scale int64) string {
+	t := strings.ToUpper(mssqlType)
+	switch t {
+	case "TINYINT", "SMALLINT", "INT", "INTEGER", "BIGINT", "BIT":
+		return "INTEGER"
+	case "REAL", "FLOAT", "DECIMAL", "NUMERIC", "MONEY", "SMALLMONEY":
+		return "REAL"
+	case "BINARY", "VARBINARY", "IMAGE", "TIMESTAMP_SQL":
+		return "BLOB"
+	default:
+		return "TEXT"
+	}
+}
+
+func (sqliteDialect) Quote(ident string) string {
+	return fmt.Sprintf(`"%s"`, ident)
+}
+
+// Qualify aplana schema+tabla en un solo identificador: un archivo SQLite no
+// tiene el concepto de schema por base de datos de origen que si tienen
+// Postgres/MySQL (solo "main" mas lo que se ATTACHee a mano).
+func (sqliteDialect) Qualify(schema, table string) string {
+	return fmt.Sprintf(`"%s_%s"`, schema, table)
+}
+
+func (sqliteDialect) Placeholder(i int) string { return "?" }
+
+// TuneSession activa WAL (necesario para que los workers en paralelo no se
+// bloqueen entre si al leer mientras otro escribe) y apaga el fsync por
+// commit; igual el archivo sigue siendo single-writer a nivel SQLite, asi
+// que no hay ganancia real de concurrencia de ESCRITURA entre chunks/tablas.
+// busy_timeout hace que un writer que choca con el lock del archivo espere
+// en vez de fallar al toque con SQLITE_BUSY; processDatabase ademas limita
+// el pool de conexiones destino a 1 para este dialecto (ver SetMaxOpenConns
+// en processDatabase), asi que esto cubre sobre todo lectores externos al
+// archivo mientras corre la migracion.
+func (sqliteDialect) TuneSession(tx *sql.Tx) error {
+	tx.Exec("PRAGMA journal_mode=WAL")
+	tx.Exec("PRAGMA busy_timeout=30000")
+	_, err := tx.Exec("PRAGMA synchronous=OFF")
+	return err
+}
+
+// AddPK: SQLite no soporta agregar una PRIMARY KEY a una tabla existente sin
+// recrearla, asi que no hay nada que ejecutar aca. El llamador se salta el
+// Exec cuando AddPK devuelve "".
+func (sqliteDialect) AddPK(schema, table string, pkCols []string) string {
+	return ""
+}
+
+// AddFK: igual que AddPK, SQLite exige que las FKs se definan en el CREATE
+// TABLE original, no via ALTER TABLE.
+func (sqliteDialect) AddFK(schema, table, fkName, col, refSchema, refTable, refCol string) string {
+	return ""
+}
+
+func (d sqliteDialect) BulkLoader(tx *sql.Tx, schema, table string, cols []string) (BulkLoader, error) {
+	placeholders := make([]string, len(cols))
+	for i := range placeholders {
+		placeholders[i] = "?"
+	}
+	insertQ := fmt.Sprintf("INSERT INTO %s VALUES (%s)", d.Qualify(schema, table), strings.Join(placeholders, ","))
+	stmt, err := tx.Prepare(insertQ)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteBulkLoader{stmt: stmt}, nil
+}
+
+type sqliteBulkLoader struct {
+	stmt *sql.Stmt
+}
+
+func (l *sqliteBulkLoader) Exec(vals ...interface{}) error {
+	_, err := l.stmt.Exec(vals...)
+	return err
+}
+
+func (l *sqliteBulkLoader) Flush() error { return nil }
+
+func (l *sqliteBulkLoader) Close() error { return l.stmt.Close() }
+
+func processDatabase(dbName, targetConnStr, driverName, runID string, dialect TargetDialect) int {
 	log.Printf("[INFO] --- INICIANDO DB: %s ---", dbName)
 
-	pgDB, err := sql.Open("postgres", pgConnStr)
+	pgDB, err := sql.Open(driverName, targetConnStr)
 	if err != nil {
-		log.Fatalf("[FATAL] Error conectando a PG: %v", err)
+		log.Fatalf("[FATAL] Error conectando al destino (%s): %v", dialect.Name(), err)
 	}
 	pgDB.SetMaxOpenConns(MaxWorkers + 10)
 	pgDB.SetMaxIdleConns(MaxWorkers + 10)
-	
-	// OPTIMIZACION DE VELOCIDAD
-	if _, err := pgDB.Exec("SET synchronous_commit TO OFF"); err != nil {
-		log.Printf("[WARN] No se pudo desactivar synchronous_commit: %v", err)
+	if dialect.Name() == "sqlite" {
+		// SQLite es single-writer: con el pool generico cada uno de los
+		// MaxWorkers goroutines abre su propia conexion/transaccion contra
+		// el mismo archivo y se pisan con SQLITE_BUSY de inmediato en vez
+		// de esperar. Una sola conexion serializa los writers a nivel de
+		// pool de Go, que es donde este tool realmente puede garantizarlo.
+		pgDB.SetMaxOpenConns(1)
+		pgDB.SetMaxIdleConns(1)
+	}
+
+	if dialect.Name() == "postgres" {
+		// OPTIMIZACION DE VELOCIDAD
+		if _, err := pgDB.Exec("SET synchronous_commit TO OFF"); err != nil {
+			log.Printf("[WARN] No se pudo desactivar synchronous_commit: %v", err)
+		}
 	}
 
 	defer pgDB.Close()
 
-	_, err = pgDB.Exec(fmt.Sprintf(`CREATE SCHEMA IF NOT EXISTS "%s"`, dbName))
-	if err != nil {
-		log.Printf("[WARN] Error creando esquema: %v", err)
+	if dialect.Name() != "sqlite" {
+		if _, err := pgDB.Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", dialect.Quote(dbName))); err != nil {
+			log.Printf("[WARN] Error creando esquema: %v", err)
+		}
+	}
+
+	if dialect.Name() == "postgres" {
+		ensureMetaSchema(pgDB)
 	}
 
 	mssqlConnStr := fmt.Sprintf("server=%s;port=%s;user id=%s;password=%s;database=%s;encrypt=disable;keepAlive=30",
@@ -123,7 +653,7 @@ func processDatabase(dbName, pgConnStr string) {
 	mssqlDB, err := sql.Open("mssql", mssqlConnStr)
 	if err != nil {
 		log.Printf("[ERROR] Error conectando a MSSQL: %v", err)
-		return
+		return 0
 	}
 	mssqlDB.SetMaxOpenConns(MaxWorkers + 10)
 	mssqlDB.SetMaxIdleConns(MaxWorkers + 10)
@@ -132,7 +662,7 @@ func processDatabase(dbName, pgConnStr string) {
 	rows, err := mssqlDB.Query("SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_TYPE = 'BASE TABLE'")
 	if err != nil {
 		log.Printf("[ERROR] Error listando tablas: %v", err)
-		return
+		return 0
 	}
 	
 	var tables []string
@@ -150,6 +680,10 @@ func processDatabase(dbName, pgConnStr string) {
 	}
 	rows.Close()
 
+	if dialect.Name() == "postgres" && (*resumeFlag || *retryFailedFlag) {
+		tables = filterTablesForRun(pgDB, mssqlDB, dbName, tables)
+	}
+
 	log.Printf("[INFO] Encontradas %d tablas. Iniciando carga masiva...", len(tables))
 
 	fkChannel := make(chan []ForeignKeySQL, len(tables))
@@ -158,7 +692,7 @@ func processDatabase(dbName, pgConnStr string) {
 
 	for w := 1; w <= MaxWorkers; w++ {
 		wg.Add(1)
-		go worker(w, dbName, mssqlDB, pgDB, jobs, fkChannel, &wg)
+		go worker(w, dbName, mssqlDB, pgDB, jobs, fkChannel, &wg, runID, dialect)
 	}
 
 	for _, t := range tables {
@@ -170,20 +704,77 @@ func processDatabase(dbName, pgConnStr string) {
 	close(fkChannel)
 
 	log.Println("[INFO] Aplicando Foreign Keys...")
-	applyForeignKeys(pgDB, fkChannel)
+	applyForeignKeys(pgDB, fkChannel, runID, dialect)
+
+	if dialect.Name() != "postgres" {
+		log.Printf("[INFO] Reconciliacion post-carga es exclusiva de --target=postgres, se omite para %s", dialect.Name())
+		return 0
+	}
+
+	log.Println("[INFO] Corriendo reconciliacion post-carga...")
+	return runReconciliation(pgDB, mssqlDB, dbName, tables, runID)
+}
+
+// filterTablesForRun aplica --resume (omite tablas que ya llegaron a
+// fks_applied) y --retry-failed (solo reprocesa tablas cuyo ultimo run
+// quedo con error), segun el estado guardado en svcs_meta.migration_runs.
+// Un "fks_applied" sin error registrado no alcanza para confiar ciegamente
+// en --resume: un batch silenciosamente truncado (swallowed error, ver el
+// patron "Silencioso") puede llegar a fks_applied con pk_checksum/rows_copied
+// ya desactualizados. Por eso se recalculan en vivo antes de saltear la tabla.
+func filterTablesForRun(pg, ms *sql.DB, schema string, tables []string) []string {
+	filtered := make([]string, 0, len(tables))
+	for _, t := range tables {
+		phase, hasError, rowsCopied, pkChecksum, found := latestRunState(pg, schema, t, *sinceFlag)
+
+		if *retryFailedFlag {
+			if found && hasError {
+				filtered = append(filtered, t)
+			}
+			continue
+		}
+
+		if found && phase == "fks_applied" && !hasError {
+			if runStateMatchesLiveData(pg, ms, schema, t, rowsCopied, pkChecksum) {
+				log.Printf("[INFO] %s: ya completado en un run anterior, se omite (--resume)", t)
+				continue
+			}
+			log.Printf("[WARN] %s: fks_applied pero el checksum/row count registrado no coincide con la carga en vivo, se reprocesa (carga parcial)", t)
+		}
+		filtered = append(filtered, t)
+	}
+	return filtered
 }
 
-func worker(id int, schema string, ms *sql.DB, pg *sql.DB, jobs <-chan string, fkChan chan<- []ForeignKeySQL, wg *sync.WaitGroup) {
+// runStateMatchesLiveData compara lo que quedo registrado en el ultimo
+// fks_applied contra el row count y el checksum de PKs actuales en Postgres.
+func runStateMatchesLiveData(pg, ms *sql.DB, schema, table string, rowsCopied, pkChecksum int64) bool {
+	if getPGRowCount(pg, schema, table) != rowsCopied {
+		return false
+	}
+	pkCols := getPrimaryKeyColumns(ms, table)
+	if len(pkCols) == 0 {
+		return true
+	}
+	return computePKChecksum(pg, schema, table, pkCols) == pkChecksum
+}
+
+func worker(id int, schema string, ms *sql.DB, pg *sql.DB, jobs <-chan string, fkChan chan<- []ForeignKeySQL, wg *sync.WaitGroup, runID string, dialect TargetDialect) {
 	defer wg.Done()
 	for table := range jobs {
-		fks := migrateTable(schema, table, ms, pg)
+		var fks []ForeignKeySQL
+		if *incrementalFlag && dialect.Name() == "postgres" {
+			fks = migrateTableIncremental(schema, table, ms, pg, runID)
+		} else {
+			fks = migrateTable(schema, table, ms, pg, runID, dialect)
+		}
 		if len(fks) > 0 {
 			fkChan <- fks
 		}
 	}
 }
 
-func migrateTable(schema, table string, ms *sql.DB, pg *sql.DB) []ForeignKeySQL {
+func migrateTable(schema, table string, ms *sql.DB, pg *sql.DB, runID string, dialect TargetDialect) []ForeignKeySQL {
 	var rows *sql.Rows
 	var err error
 
@@ -194,6 +785,7 @@ func migrateTable(schema, table string, ms *sql.DB, pg *sql.DB) []ForeignKeySQL
 	}
 	if err != nil {
 		log.Printf("[ERROR] [FINAL] %s: No se pudo leer origen: %v", table, err)
+		recordRunPhaseSafe(dialect, pg, runID, schema, table, "schema_created", 0, 0, err.Error())
 		return nil
 	}
 	defer rows.Close()
@@ -201,18 +793,25 @@ func migrateTable(schema, table string, ms *sql.DB, pg *sql.DB) []ForeignKeySQL
 	cols, _ := rows.Columns()
 	colTypes, _ := rows.ColumnTypes()
 
-	pg.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS "%s"."%s" CASCADE`, schema, table))
-	
-	createSQL := fmt.Sprintf(`CREATE TABLE "%s"."%s" (`, schema, table)
+	tableRef := dialect.Qualify(schema, table)
+	dropSQL := fmt.Sprintf("DROP TABLE IF EXISTS %s", tableRef)
+	if dialect.Name() == "postgres" {
+		dropSQL += " CASCADE"
+	}
+	pg.Exec(dropSQL)
+
+	safeCols := make([]string, len(cols))
+	createSQL := fmt.Sprintf("CREATE TABLE %s (", tableRef)
 	for i, c := range cols {
-		pgType := "TEXT"
+		destType := "TEXT"
 		if i < len(colTypes) {
 			precision, scale, _ := colTypes[i].DecimalSize()
-			pgType = getPostgresType(colTypes[i].DatabaseTypeName(), precision, scale)
+			destType = dialect.MapType(colTypes[i].DatabaseTypeName(), precision, scale)
 		}
 		// Limpiamos tambien los nombres de columnas por si acaso
 		safeCol := strings.TrimSpace(strings.ReplaceAll(c, "\"", ""))
-		createSQL += fmt.Sprintf(`"%s" %s`, safeCol, pgType)
+		safeCols[i] = safeCol
+		createSQL += fmt.Sprintf("%s %s", dialect.Quote(safeCol), destType)
 		if i < len(cols)-1 {
 			createSQL += ", "
 		}
@@ -221,89 +820,114 @@ func migrateTable(schema, table string, ms *sql.DB, pg *sql.DB) []ForeignKeySQL
 
 	if _, err := pg.Exec(createSQL); err != nil {
 		log.Printf("[ERROR] %s: Fallo create table: %v", table, err)
+		recordRunPhaseSafe(dialect, pg, runID, schema, table, "schema_created", 0, 0, err.Error())
 		return nil
 	}
+	recordRunPhaseSafe(dialect, pg, runID, schema, table, "schema_created", 0, 0, "")
 
-	values := make([]interface{}, len(cols))
-	scanArgs := make([]interface{}, len(cols))
+	pkCols := getPrimaryKeyColumns(ms, table)
+
+	var count int64
+	if chunkCol, chunkable := singleChunkablePK(ms, table, pkCols); chunkable && estimateRowCount(ms, table) > ChunkThreshold {
+		rows.Close()
+		count = migrateTableChunked(schema, table, ms, pg, safeCols, colTypes, chunkCol, dialect)
+	} else {
+		count = loadRowsIntoPG(dialect, pg, schema, table, safeCols, rows, colTypes)
+	}
+
+	var pkChecksum int64
+	if len(pkCols) > 0 {
+		if addPKSQL := dialect.AddPK(schema, table, pkCols); addPKSQL != "" {
+			pg.Exec(addPKSQL)
+		}
+		if dialect.Name() == "postgres" {
+			pkChecksum = computePKChecksum(pg, schema, table, pkCols)
+		}
+	}
+	recordRunPhaseSafe(dialect, pg, runID, schema, table, "data_loaded", count, pkChecksum, "")
+	recordRunPhaseSafe(dialect, pg, runID, schema, table, "pk_added", count, pkChecksum, "")
+
+	if count > 0 {
+		log.Printf("[OK] %s: %d filas", table, count)
+	}
+
+	fks := getForeignKeys(ms, schema, table, dialect)
+	if len(fks) == 0 {
+		recordRunPhaseSafe(dialect, pg, runID, schema, table, "fks_applied", count, pkChecksum, "")
+	} else {
+		recordRunPhaseSafe(dialect, pg, runID, schema, table, "fks_pending", count, pkChecksum, "")
+	}
+	return fks
+}
+
+// loadRowsIntoPG vuelca un cursor de MSSQL ya abierto hacia la tabla destino
+// en batches de BatchSize, commiteando cada batch. La usan tanto la carga completa como cada chunk de migrateTableChunked.
+// El mecanismo de carga en si (COPY, INSERT multi-fila, INSERT preparado) lo
+// decide el BulkLoader del TargetDialect; esta funcion solo sabe iterar el
+// cursor de origen, coercionar valores y respetar el ciclo de batch/commit.
+func loadRowsIntoPG(dialect TargetDialect, pg *sql.DB, schema, table string, safeCols []string, rows *sql.Rows, colTypes []*sql.ColumnType) int64 {
+	defer rows.Close()
+
+	values := make([]interface{}, len(safeCols))
+	scanArgs := make([]interface{}, len(safeCols))
 	for i := range values {
 		scanArgs[i] = &values[i]
 	}
 
 	tx, _ := pg.Begin()
-	tx.Exec("SET synchronous_commit TO OFF") // Optimizacion por transaccion
+	dialect.TuneSession(tx)
+	loader, err := dialect.BulkLoader(tx, schema, table, safeCols)
+	if err != nil {
+		log.Printf("[ERROR] %s: no se pudo preparar la carga masiva (%s): %v", table, dialect.Name(), err)
+		tx.Rollback()
+		return 0
+	}
 
 	var count int64 = 0
-	placeholders := make([]string, len(cols))
-	for i := range placeholders { placeholders[i] = fmt.Sprintf("$%d", i+1) }
-	insertQ := fmt.Sprintf(`INSERT INTO "%s"."%s" VALUES (%s)`, schema, table, strings.Join(placeholders, ","))
-	stmt, _ := tx.Prepare(insertQ)
-	defer stmt.Close()
+
+	flush := func() {
+		if err := loader.Flush(); err != nil {
+			log.Printf("[ERROR] %s: fallo el flush del batch (count=%d), se revierte: %v", table, count, err)
+			loader.Close()
+			tx.Rollback()
+		} else {
+			loader.Close()
+			tx.Commit()
+		}
+		tx, _ = pg.Begin()
+		dialect.TuneSession(tx)
+		loader, _ = dialect.BulkLoader(tx, schema, table, safeCols)
+	}
 
 	for rows.Next() {
 		if err := rows.Scan(scanArgs...); err != nil { continue }
-		
-		finalVals := make([]interface{}, len(cols))
+
+		finalVals := make([]interface{}, len(values))
 		for i, v := range values {
-			if v == nil {
-				finalVals[i] = nil
-			} else {
-				typeName := ""
-				if i < len(colTypes) { typeName = strings.ToUpper(colTypes[i].DatabaseTypeName()) }
-
-				switch t := v.(type) {
-				case []byte:
-					if typeName == "BIT" || typeName == "BOOLEAN" {
-						if len(t) > 0 && t[0] == 1 { finalVals[i] = true } else { finalVals[i] = false }
-					} else if typeName == "UNIQUEIDENTIFIER" {
-						if len(t) == 16 {
-							finalVals[i] = fmt.Sprintf("%x-%x-%x-%x-%x", t[0:4], t[4:6], t[6:8], t[8:10], t[10:])
-						} else {
-							finalVals[i] = nil
-						}
-					} else if strings.Contains(typeName, "BINARY") || strings.Contains(typeName, "IMAGE") {
-						finalVals[i] = t
-					} else {
-						strVal := string(t)
-						finalVals[i] = strings.ReplaceAll(strVal, "\x00", "")
-					}
-				case string:
-					finalVals[i] = strings.ReplaceAll(t, "\x00", "")
-				case bool:
-					finalVals[i] = t
-				default:
-					finalVals[i] = v
-				}
-			}
+			typeName := ""
+			if i < len(colTypes) { typeName = colTypes[i].DatabaseTypeName() }
+			finalVals[i] = coerceValue(v, typeName)
 		}
 
-		if _, err := stmt.Exec(finalVals...); err != nil {
+		if err := loader.Exec(finalVals...); err != nil {
 			// Silencioso
 		}
 
 		count++
 		if count%BatchSize == 0 {
-			tx.Commit()
-			tx, _ = pg.Begin()
-			tx.Exec("SET synchronous_commit TO OFF")
-			stmt, _ = tx.Prepare(insertQ)
+			flush()
 		}
 	}
-	tx.Commit()
-
-	pkCols := getPrimaryKeyColumns(ms, table)
-	if len(pkCols) > 0 {
-		pkName := fmt.Sprintf("pk_%s_%s", schema, table)
-		if len(pkName) > 63 { pkName = pkName[:63] }
-		colsStr := strings.Join(pkCols, `", "`)
-		pg.Exec(fmt.Sprintf(`ALTER TABLE "%s"."%s" ADD CONSTRAINT "%s" PRIMARY KEY ("%s")`, schema, table, pkName, colsStr))
+	if err := loader.Flush(); err != nil {
+		log.Printf("[ERROR] %s: fallo el flush final del batch (count=%d), se revierte: %v", table, count, err)
+		loader.Close()
+		tx.Rollback()
+	} else {
+		loader.Close()
+		tx.Commit()
 	}
 
-	if count > 0 {
-		log.Printf("[OK] %s: %d filas", table, count)
-	}
-
-	return getForeignKeys(ms, schema, table)
+	return count
 }
 
 func getPrimaryKeyColumns(db *sql.DB, tableName string) []string {
@@ -320,7 +944,455 @@ func getPrimaryKeyColumns(db *sql.DB, tableName string) []string {
 	return pks
 }
 
-func getForeignKeys(db *sql.DB, schema, tableName string) []ForeignKeySQL {
+// --- CHUNKING INTRA-TABLA (tablas grandes con PK ordenable) ---
+
+var chunkablePKTypes = map[string]bool{
+	"TINYINT": true, "SMALLINT": true, "INT": true, "BIGINT": true, "UNIQUEIDENTIFIER": true,
+}
+
+func getColumnSQLType(ms *sql.DB, tableName, colName string) string {
+	query := `SELECT ty.name FROM sys.columns c INNER JOIN sys.types ty ON c.user_type_id = ty.user_type_id INNER JOIN sys.objects o ON o.object_id = c.object_id WHERE o.name = @p1 AND c.name = @p2`
+	var t string
+	if err := ms.QueryRow(query, tableName, colName).Scan(&t); err != nil {
+		return ""
+	}
+	return t
+}
+
+// singleChunkablePK dice si la tabla tiene una unica columna de PK de un
+// tipo sobre el que tiene sentido partir el keyspace (entero o uniqueidentifier).
+func singleChunkablePK(ms *sql.DB, tableName string, pkCols []string) (string, bool) {
+	if len(pkCols) != 1 {
+		return "", false
+	}
+	t := strings.ToUpper(getColumnSQLType(ms, tableName, pkCols[0]))
+	return pkCols[0], chunkablePKTypes[t]
+}
+
+func estimateRowCount(ms *sql.DB, tableName string) int64 {
+	query := `SELECT SUM(p.rows) FROM sys.partitions p INNER JOIN sys.objects o ON o.object_id = p.object_id WHERE o.name = @p1 AND p.index_id IN (0, 1)`
+	var count sql.NullInt64
+	if err := ms.QueryRow(query, tableName).Scan(&count); err != nil || !count.Valid {
+		return 0
+	}
+	return count.Int64
+}
+
+type chunkRange struct {
+	Lo    interface{}
+	Hi    interface{}
+	HasHi bool
+}
+
+// getChunkRanges usa NTILE sobre la PK para partir la tabla en n grupos de
+// tamano parejo, y arma con buildChunkRanges un rango por grupo a partir del
+// limite inferior de cada uno (el ultimo queda abierto para no perder filas
+// insertadas despues del calculo).
+func getChunkRanges(ms *sql.DB, tableName, pkCol string, n int) []chunkRange {
+	query := fmt.Sprintf(`SELECT MIN(v) AS lo FROM (SELECT [%s] AS v, NTILE(@p1) OVER (ORDER BY [%s]) AS grp FROM [%s]) t GROUP BY grp ORDER BY grp`,
+		pkCol, pkCol, tableName)
+	rows, err := ms.Query(query, n)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var los []interface{}
+	for rows.Next() {
+		var lo interface{}
+		if err := rows.Scan(&lo); err != nil { continue }
+		los = append(los, lo)
+	}
+
+	return buildChunkRanges(los)
+}
+
+// buildChunkRanges convierte los limites inferiores de cada grupo NTILE en
+// rangos [Lo, Hi): cada chunk toma el Lo del siguiente grupo como su Hi, y el
+// ultimo chunk queda con HasHi=false (abierto) para no perder filas
+// insertadas en la tabla origen despues de calcular los rangos. Separada de
+// getChunkRanges para poder testear la aritmetica de limites sin una DB.
+func buildChunkRanges(los []interface{}) []chunkRange {
+	ranges := make([]chunkRange, len(los))
+	for i, lo := range los {
+		ranges[i] = chunkRange{Lo: lo}
+		if i < len(los)-1 {
+			ranges[i].Hi = los[i+1]
+			ranges[i].HasHi = true
+		}
+	}
+	return ranges
+}
+
+// migrateTableChunked reparte una tabla grande en rangos de PK y los carga
+// en paralelo con un pool secundario de goroutines, cada una con su propia
+// transaccion/stream hacia la misma tabla destino. El orden de commit no
+// importa porque la PK (y los FKs) se agregan recien cuando todos los
+// chunks terminan.
+func migrateTableChunked(schema, table string, ms, pg *sql.DB, safeCols []string, colTypes []*sql.ColumnType, pkCol string, dialect TargetDialect) int64 {
+	ranges := getChunkRanges(ms, table, pkCol, ChunkWorkers)
+	if len(ranges) == 0 {
+		log.Printf("[WARN] %s: no se pudieron calcular rangos de chunking, usando carga completa", table)
+		rows, err := ms.Query(fmt.Sprintf("SELECT * FROM [%s]", table))
+		if err != nil {
+			log.Printf("[ERROR] %s: No se pudo leer origen: %v", table, err)
+			return 0
+		}
+		return loadRowsIntoPG(dialect, pg, schema, table, safeCols, rows, colTypes)
+	}
+
+	log.Printf("[INFO] %s: tabla grande, dividiendo en %d chunks por [%s]", table, len(ranges), pkCol)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var total int64
+	sem := make(chan struct{}, ChunkWorkers)
+
+	for i, r := range ranges {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(idx int, rng chunkRange) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var query string
+			var args []interface{}
+			if rng.HasHi {
+				query = fmt.Sprintf("SELECT * FROM [%s] WHERE [%s] >= @p1 AND [%s] < @p2 ORDER BY [%s]", table, pkCol, pkCol, pkCol)
+				args = []interface{}{rng.Lo, rng.Hi}
+			} else {
+				query = fmt.Sprintf("SELECT * FROM [%s] WHERE [%s] >= @p1 ORDER BY [%s]", table, pkCol, pkCol)
+				args = []interface{}{rng.Lo}
+			}
+
+			chunkRows, err := ms.Query(query, args...)
+			if err != nil {
+				log.Printf("[ERROR] %s: chunk %d/%d fallo: %v", table, idx+1, len(ranges), err)
+				return
+			}
+
+			n := loadRowsIntoPG(dialect, pg, schema, table, safeCols, chunkRows, colTypes)
+			mu.Lock()
+			total += n
+			mu.Unlock()
+			log.Printf("[OK] %s: chunk %d/%d, %d filas", table, idx+1, len(ranges), n)
+		}(i, r)
+	}
+	wg.Wait()
+
+	return total
+}
+
+// --- MODO INCREMENTAL (Change Tracking) ---
+
+func ensureMetaSchema(pg *sql.DB) {
+	pg.Exec(`CREATE SCHEMA IF NOT EXISTS svcs_meta`)
+	pg.Exec(`CREATE TABLE IF NOT EXISTS svcs_meta.sync_state (
+		schema_name TEXT NOT NULL,
+		table_name TEXT NOT NULL,
+		last_version BIGINT,
+		last_run_at TIMESTAMP,
+		PRIMARY KEY (schema_name, table_name)
+	)`)
+	pg.Exec(`CREATE TABLE IF NOT EXISTS svcs_meta.migration_runs (
+		id BIGSERIAL PRIMARY KEY,
+		run_id TEXT NOT NULL,
+		source_db TEXT NOT NULL,
+		table_name TEXT NOT NULL,
+		phase TEXT NOT NULL,
+		rows_copied BIGINT,
+		pk_checksum BIGINT,
+		started_at TIMESTAMP NOT NULL DEFAULT now(),
+		finished_at TIMESTAMP,
+		error TEXT
+	)`)
+	pg.Exec(`CREATE TABLE IF NOT EXISTS svcs_meta.reconciliation (
+		id BIGSERIAL PRIMARY KEY,
+		run_id TEXT NOT NULL,
+		source_db TEXT NOT NULL,
+		table_name TEXT NOT NULL,
+		src_rows BIGINT,
+		dst_rows BIGINT,
+		src_hash BIGINT,
+		dst_hash BIGINT,
+		status TEXT NOT NULL,
+		checked_at TIMESTAMP NOT NULL DEFAULT now()
+	)`)
+}
+
+// --- MIGRACION RESUMIBLE (goose-style, keyed por source_db + table) ---
+
+// recordRunPhaseSafe es el punto de entrada que usan migrateTable y
+// applyForeignKeys: migration_runs vive en svcs_meta, asi que solo tiene
+// sentido escribir ahi cuando el destino es Postgres. migrateTableIncremental
+// y runReconciliation solo corren con destino Postgres y llaman directo a
+// recordRunPhase.
+func recordRunPhaseSafe(dialect TargetDialect, pg *sql.DB, runID, schema, table, phase string, rowsCopied, pkChecksum int64, errMsg string) {
+	if dialect.Name() != "postgres" {
+		return
+	}
+	recordRunPhase(pg, runID, schema, table, phase, rowsCopied, pkChecksum, errMsg)
+}
+
+// recordRunPhase agrega una entrada al log de fases de migration_runs.
+// Es append-only a proposito (como goose/xormigrate) para poder auditar
+// corridas previas en vez de pisar el ultimo estado.
+func recordRunPhase(pg *sql.DB, runID, schema, table, phase string, rowsCopied, pkChecksum int64, errMsg string) {
+	tx, err := pg.Begin()
+	if err != nil {
+		return
+	}
+
+	var errArg interface{}
+	if errMsg != "" {
+		errArg = errMsg
+	}
+	var finishedAt interface{}
+	if phase == "fks_applied" || errMsg != "" {
+		finishedAt = time.Now()
+	}
+
+	_, err = tx.Exec(`INSERT INTO svcs_meta.migration_runs (run_id, source_db, table_name, phase, rows_copied, pk_checksum, finished_at, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		runID, schema, table, phase, rowsCopied, pkChecksum, finishedAt, errArg)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("[WARN] %s: no se pudo registrar fase %s: %v", table, phase, err)
+		return
+	}
+	tx.Commit()
+}
+
+// latestRunState devuelve la fase, el estado de error y el rows_copied/
+// pk_checksum registrados por la ultima corrida para (schema, table),
+// opcionalmente filtrando por --since.
+func latestRunState(pg *sql.DB, schema, table, since string) (phase string, hasError bool, rowsCopied, pkChecksum int64, found bool) {
+	query := `SELECT phase, error, rows_copied, pk_checksum FROM svcs_meta.migration_runs WHERE source_db = $1 AND table_name = $2`
+	args := []interface{}{schema, table}
+	if since != "" {
+		query += " AND started_at >= $3"
+		args = append(args, since)
+	}
+	query += " ORDER BY id DESC LIMIT 1"
+
+	var errVal sql.NullString
+	if err := pg.QueryRow(query, args...).Scan(&phase, &errVal, &rowsCopied, &pkChecksum); err != nil {
+		return "", false, 0, 0, false
+	}
+	return phase, errVal.Valid && errVal.String != "", rowsCopied, pkChecksum, true
+}
+
+// computePKChecksum calcula un checksum XOR de los PKs ya cargados en
+// Postgres, para que --resume pueda detectar cargas de datos parciales
+// ademas de comparar el row count.
+func computePKChecksum(pg *sql.DB, schema, table string, pkCols []string) int64 {
+	quoted := make([]string, len(pkCols))
+	for i, c := range pkCols {
+		quoted[i] = fmt.Sprintf(`"%s"`, c)
+	}
+	query := fmt.Sprintf(`SELECT %s FROM "%s"."%s"`, strings.Join(quoted, ","), schema, table)
+	rows, err := pg.Query(query)
+	if err != nil {
+		return 0
+	}
+	defer rows.Close()
+
+	vals := make([]interface{}, len(pkCols))
+	scanArgs := make([]interface{}, len(pkCols))
+	for i := range vals {
+		scanArgs[i] = &vals[i]
+	}
+
+	var checksum int64
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil { continue }
+		h := fnv.New64a()
+		for _, v := range vals {
+			fmt.Fprintf(h, "%v|", v)
+		}
+		checksum ^= int64(h.Sum64())
+	}
+	return checksum
+}
+
+func getSyncState(pg *sql.DB, schema, table string) (int64, bool) {
+	var lastVersion sql.NullInt64
+	err := pg.QueryRow(`SELECT last_version FROM svcs_meta.sync_state WHERE schema_name = $1 AND table_name = $2`, schema, table).Scan(&lastVersion)
+	if err != nil || !lastVersion.Valid {
+		return 0, false
+	}
+	return lastVersion.Int64, true
+}
+
+func setSyncState(pg *sql.DB, schema, table string, version int64) {
+	pg.Exec(`INSERT INTO svcs_meta.sync_state (schema_name, table_name, last_version, last_run_at) VALUES ($1, $2, $3, now())
+		ON CONFLICT (schema_name, table_name) DO UPDATE SET last_version = $3, last_run_at = now()`, schema, table, version)
+}
+
+func isChangeTrackingEnabled(ms *sql.DB, tableName string) bool {
+	query := `SELECT COUNT(*) FROM sys.change_tracking_tables ct INNER JOIN sys.tables t ON t.object_id = ct.object_id WHERE t.name = @p1`
+	var count int
+	if err := ms.QueryRow(query, tableName).Scan(&count); err != nil {
+		return false
+	}
+	return count > 0
+}
+
+func currentChangeTrackingVersion(ms *sql.DB) (int64, error) {
+	var version int64
+	err := ms.QueryRow("SELECT CHANGE_TRACKING_CURRENT_VERSION()").Scan(&version)
+	return version, err
+}
+
+// migrateTableIncremental aplica solo los cambios desde el ultimo
+// last_version via CHANGETABLE(CHANGES ...). Si la tabla no tiene Change
+// Tracking habilitado o no tenemos un baseline todavia, cae a migrateTable.
+func migrateTableIncremental(schema, table string, ms *sql.DB, pg *sql.DB, runID string) []ForeignKeySQL {
+	if !isChangeTrackingEnabled(ms, table) {
+		log.Printf("[WARN] %s: sin Change Tracking habilitado, usando carga completa", table)
+		return migrateTable(schema, table, ms, pg, runID, postgresDialect{})
+	}
+
+	pkCols := getPrimaryKeyColumns(ms, table)
+	if len(pkCols) == 0 {
+		log.Printf("[WARN] %s: sin PK, no se puede aplicar CDC, usando carga completa", table)
+		return migrateTable(schema, table, ms, pg, runID, postgresDialect{})
+	}
+
+	lastVersion, ok := getSyncState(pg, schema, table)
+	if !ok {
+		fks := migrateTable(schema, table, ms, pg, runID, postgresDialect{})
+		if version, err := currentChangeTrackingVersion(ms); err == nil {
+			setSyncState(pg, schema, table, version)
+		} else {
+			log.Printf("[ERROR] %s: no se pudo leer CHANGE_TRACKING_CURRENT_VERSION: %v", table, err)
+		}
+		return fks
+	}
+
+	// El watermark se captura ANTES de correr la query de cambios: si se
+	// captura despues, cualquier commit en el origen entre la query y la
+	// lectura de la version queda con un SYS_CHANGE_VERSION <= lastVersion
+	// recien guardado y se pierde para siempre (nunca se vuelve a pedir).
+	nextVersion, err := currentChangeTrackingVersion(ms)
+	if err != nil {
+		log.Printf("[ERROR] %s: no se pudo leer CHANGE_TRACKING_CURRENT_VERSION, usando carga completa: %v", table, err)
+		return migrateTable(schema, table, ms, pg, runID, postgresDialect{})
+	}
+
+	joinCond := make([]string, len(pkCols))
+	ctPkCols := make([]string, len(pkCols))
+	for i, c := range pkCols {
+		joinCond[i] = fmt.Sprintf("CT.[%s] = T.[%s]", c, c)
+		ctPkCols[i] = fmt.Sprintf("CT.[%s]", c)
+	}
+	// Los PK salen de CT, no de T.*: para un delete la fila de T ya no
+	// existe, el LEFT JOIN la trae toda en NULL y sin esto el DELETE
+	// terminaria ejecutandose con un PK nulo que no matchea nada.
+	query := fmt.Sprintf(`SELECT CT.SYS_CHANGE_OPERATION, %s, T.* FROM CHANGETABLE(CHANGES [%s], @p1) AS CT LEFT JOIN [%s] T ON %s`,
+		strings.Join(ctPkCols, ", "), table, table, strings.Join(joinCond, " AND "))
+
+	rows, err := ms.Query(query, lastVersion)
+	if err != nil {
+		log.Printf("[ERROR] %s: fallo CHANGETABLE, usando carga completa: %v", table, err)
+		return migrateTable(schema, table, ms, pg, runID, postgresDialect{})
+	}
+	defer rows.Close()
+
+	cols, _ := rows.Columns()
+	colTypes, _ := rows.ColumnTypes()
+	values := make([]interface{}, len(cols))
+	scanArgs := make([]interface{}, len(cols))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	pkQuoted := make([]string, len(pkCols))
+	for i, c := range pkCols {
+		pkQuoted[i] = fmt.Sprintf(`"%s"`, c)
+	}
+
+	tx, _ := pg.Begin()
+	var applied, failed int64
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil { continue }
+
+		op, _ := values[0].(string)
+		pkVals := values[1 : 1+len(pkCols)]
+		pkTypes := colTypes[1 : 1+len(pkCols)]
+		rowCols := cols[1+len(pkCols):]
+		rowVals := values[1+len(pkCols):]
+		rowTypes := colTypes[1+len(pkCols):]
+
+		// denisenkom/go-mssqldb devuelve UNIQUEIDENTIFIER como []byte crudo,
+		// no el UUID con guiones que espera la columna Postgres: sin
+		// coerceValue un PK GUID no matchea ninguna fila y el delete/upsert
+		// falla en silencio (el mismo bug que el resto de la carga ya evita).
+		finalPkVals := make([]interface{}, len(pkVals))
+		for i, v := range pkVals {
+			typeName := ""
+			if i < len(pkTypes) { typeName = pkTypes[i].DatabaseTypeName() }
+			finalPkVals[i] = coerceValue(v, typeName)
+		}
+
+		var execErr error
+		if op == "D" {
+			where := make([]string, len(pkCols))
+			for i, c := range pkQuoted {
+				where[i] = fmt.Sprintf("%s = $%d", c, i+1)
+			}
+			_, execErr = tx.Exec(fmt.Sprintf(`DELETE FROM "%s"."%s" WHERE %s`, schema, table, strings.Join(where, " AND ")), finalPkVals...)
+		} else {
+			finalVals := make([]interface{}, len(rowCols))
+			placeholders := make([]string, len(rowCols))
+			updates := make([]string, 0, len(rowCols))
+			for i, c := range rowCols {
+				typeName := ""
+				if i < len(rowTypes) { typeName = rowTypes[i].DatabaseTypeName() }
+				finalVals[i] = coerceValue(rowVals[i], typeName)
+				placeholders[i] = fmt.Sprintf("$%d", i+1)
+				safeCol := strings.TrimSpace(strings.ReplaceAll(c, "\"", ""))
+				updates = append(updates, fmt.Sprintf(`"%s" = EXCLUDED."%s"`, safeCol, safeCol))
+			}
+			upsertQ := fmt.Sprintf(`INSERT INTO "%s"."%s" VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s`,
+				schema, table, strings.Join(placeholders, ","), strings.Join(pkQuoted, ","), strings.Join(updates, ","))
+			_, execErr = tx.Exec(upsertQ, finalVals...)
+		}
+		if execErr != nil {
+			failed++
+			log.Printf("[ERROR] %s: fallo aplicar cambio incremental (op=%s pk=%v): %v", table, op, finalPkVals, execErr)
+			continue
+		}
+		applied++
+	}
+	tx.Commit()
+
+	setSyncState(pg, schema, table, nextVersion)
+	if failed > 0 {
+		log.Printf("[ERROR] %s: %d cambios incrementales fallaron al aplicar", table, failed)
+	}
+	if applied > 0 {
+		log.Printf("[OK] %s: %d cambios aplicados (incremental)", table, applied)
+	}
+
+	errMsg := ""
+	if failed > 0 {
+		errMsg = fmt.Sprintf("%d cambios incrementales fallaron al aplicar", failed)
+	}
+
+	pkChecksum := computePKChecksum(pg, schema, table, pkCols)
+	recordRunPhase(pg, runID, schema, table, "data_loaded", applied, pkChecksum, errMsg)
+
+	fks := getForeignKeys(ms, schema, table, postgresDialect{})
+	if len(fks) == 0 {
+		recordRunPhase(pg, runID, schema, table, "fks_applied", applied, pkChecksum, errMsg)
+	} else {
+		recordRunPhase(pg, runID, schema, table, "fks_pending", applied, pkChecksum, errMsg)
+	}
+	return fks
+}
+
+func getForeignKeys(db *sql.DB, schema, tableName string, dialect TargetDialect) []ForeignKeySQL {
 	query := `SELECT obj.name, col1.name, tab2.name, col2.name FROM sys.foreign_key_columns fkc INNER JOIN sys.objects obj ON obj.object_id = fkc.constraint_object_id INNER JOIN sys.tables tab1 ON tab1.object_id = fkc.parent_object_id INNER JOIN sys.columns col1 ON col1.column_id = fkc.parent_column_id AND col1.object_id = tab1.object_id INNER JOIN sys.tables tab2 ON tab2.object_id = fkc.referenced_object_id INNER JOIN sys.columns col2 ON col2.column_id = fkc.referenced_column_id AND col2.object_id = tab2.object_id WHERE tab1.name = @p1`
 	rows, err := db.Query(query, tableName)
 	if err != nil { return nil }
@@ -329,18 +1401,208 @@ func getForeignKeys(db *sql.DB, schema, tableName string) []ForeignKeySQL {
 	for rows.Next() {
 		var fkName, col, refTable, refCol string
 		if err := rows.Scan(&fkName, &col, &refTable, &refCol); err == nil {
-			pgFkName := fmt.Sprintf("fk_%s_%s_%s", tableName, col, refTable)
-			if len(pgFkName) > 63 { pgFkName = pgFkName[:63] }
-			sql := fmt.Sprintf(`ALTER TABLE "%s"."%s" ADD CONSTRAINT "%s" FOREIGN KEY ("%s") REFERENCES "%s"."%s" ("%s")`, schema, tableName, pgFkName, col, schema, refTable, refCol)
-			fks = append(fks, ForeignKeySQL{ConstraintName: pgFkName, SQL: sql})
+			destFkName := fmt.Sprintf("fk_%s_%s_%s", tableName, col, refTable)
+			if len(destFkName) > 63 { destFkName = destFkName[:63] }
+			sql := dialect.AddFK(schema, tableName, destFkName, col, schema, refTable, refCol)
+			if sql == "" {
+				continue
+			}
+			fks = append(fks, ForeignKeySQL{SourceDB: schema, TableName: tableName, ConstraintName: destFkName, SQL: sql})
 		}
 	}
 	return fks
 }
 
-func applyForeignKeys(pg *sql.DB, fkChan <-chan []ForeignKeySQL) {
+func applyForeignKeys(pg *sql.DB, fkChan <-chan []ForeignKeySQL, runID string, dialect TargetDialect) {
 	var allFks []ForeignKeySQL
 	for fks := range fkChan { allFks = append(allFks, fks...) }
 	log.Printf("[INFO] Procesando %d FKs...", len(allFks))
-	for _, fk := range allFks { pg.Exec(fk.SQL) }
+
+	byTable := make(map[string][]ForeignKeySQL)
+	var order []string
+	for _, fk := range allFks {
+		key := fk.SourceDB + "." + fk.TableName
+		if _, ok := byTable[key]; !ok {
+			order = append(order, key)
+		}
+		byTable[key] = append(byTable[key], fk)
+	}
+
+	for _, key := range order {
+		fks := byTable[key]
+		for _, fk := range fks {
+			pg.Exec(fk.SQL)
+		}
+		// fks_applied hereda rows_copied/pk_checksum del fks_pending previo
+		// para que --resume pueda compararlos contra la carga en vivo.
+		var rowsCopied, pkChecksum int64
+		if dialect.Name() == "postgres" {
+			_, _, rowsCopied, pkChecksum, _ = latestRunState(pg, fks[0].SourceDB, fks[0].TableName, "")
+		}
+		recordRunPhaseSafe(dialect, pg, runID, fks[0].SourceDB, fks[0].TableName, "fks_applied", rowsCopied, pkChecksum, "")
+	}
+}
+
+// --- RECONCILIACION POST-CARGA ---
+
+// reconcileMSTypes son los tipos MSSQL que entran al checksum de
+// reconciliacion por default (numericos, fecha/hora y texto). BYTEA/binarios
+// quedan afuera porque hashear su representacion como texto no es comparable
+// de forma estable entre MSSQL y Postgres.
+var reconcileMSTypes = map[string]bool{
+	"TINYINT": true, "SMALLINT": true, "INT": true, "BIGINT": true,
+	"DECIMAL": true, "NUMERIC": true, "MONEY": true, "SMALLMONEY": true, "REAL": true, "FLOAT": true,
+	"DATE": true, "DATETIME": true, "DATETIME2": true, "SMALLDATETIME": true, "TIME": true,
+	"CHAR": true, "NCHAR": true, "VARCHAR": true, "NVARCHAR": true, "TEXT": true, "NTEXT": true,
+}
+
+func getReconciliationColumns(ms *sql.DB, tableName string) []string {
+	query := `SELECT c.name, ty.name FROM sys.columns c INNER JOIN sys.types ty ON c.user_type_id = ty.user_type_id INNER JOIN sys.objects o ON o.object_id = c.object_id WHERE o.name = @p1 ORDER BY c.column_id`
+	rows, err := ms.Query(query, tableName)
+	if err != nil { return nil }
+	defer rows.Close()
+
+	var cols []string
+	for rows.Next() {
+		var name, typ string
+		if err := rows.Scan(&name, &typ); err != nil { continue }
+		if reconcileMSTypes[strings.ToUpper(typ)] {
+			cols = append(cols, strings.TrimSpace(name))
+		}
+	}
+	return cols
+}
+
+func getMSRowCount(ms *sql.DB, tableName string) int64 {
+	var count int64
+	ms.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM [%s]", tableName)).Scan(&count)
+	return count
+}
+
+func getPGRowCount(pg *sql.DB, schema, tableName string) int64 {
+	var count int64
+	pg.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM "%s"."%s"`, schema, tableName)).Scan(&count)
+	return count
+}
+
+// computeMSChecksum y computePGChecksum calculan el checksum de reconciliacion
+// como un XOR de hash fnv64a por fila (mismo esquema que computePKChecksum),
+// no con CHECKSUM_AGG/hashtext nativos de cada motor: esos dos agregados usan
+// algoritmos distintos y nunca van a coincidir entre MSSQL y Postgres aunque
+// los datos sean identicos, lo que los vuelve inutiles para comparar. Al
+// hashear del lado del cliente con el mismo algoritmo en ambos engines, el
+// resultado si es comparable bit a bit y puede usarse para gatear mismatches.
+func computeMSChecksum(ms *sql.DB, tableName string, cols []string) int64 {
+	if len(cols) == 0 {
+		return 0
+	}
+	quoted := make([]string, len(cols))
+	for i, c := range cols {
+		quoted[i] = fmt.Sprintf("[%s]", c)
+	}
+	query := fmt.Sprintf("SELECT %s FROM [%s]", strings.Join(quoted, ","), tableName)
+	return computeRowChecksum(ms, query, len(cols), tableName, "mssql")
+}
+
+func computePGChecksum(pg *sql.DB, schema, tableName string, cols []string) int64 {
+	if len(cols) == 0 {
+		return 0
+	}
+	quoted := make([]string, len(cols))
+	for i, c := range cols {
+		safeCol := strings.TrimSpace(strings.ReplaceAll(c, "\"", ""))
+		quoted[i] = fmt.Sprintf(`"%s"`, safeCol)
+	}
+	query := fmt.Sprintf(`SELECT %s FROM "%s"."%s"`, strings.Join(quoted, ","), schema, tableName)
+	return computeRowChecksum(pg, query, len(cols), tableName, "postgres")
+}
+
+// computeRowChecksum recorre el cursor fila por fila con memoria acotada (un
+// solo juego de valores reutilizado, igual que loadRowsIntoPG), sin cargar la
+// tabla completa en memoria. Para tablas de decenas de millones de filas esto
+// sigue siendo un segundo full scan completo por motor, asi que loguea
+// progreso cada ReconcileProgressEvery filas igual que el loader chunkeado
+// en vez de quedarse callado por horas.
+func computeRowChecksum(db *sql.DB, query string, numCols int, tableName, engine string) int64 {
+	rows, err := db.Query(query)
+	if err != nil {
+		return 0
+	}
+	defer rows.Close()
+
+	vals := make([]interface{}, numCols)
+	scanArgs := make([]interface{}, numCols)
+	for i := range vals {
+		scanArgs[i] = &vals[i]
+	}
+
+	var checksum int64
+	var n int64
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil { continue }
+		h := fnv.New64a()
+		for _, v := range vals {
+			fmt.Fprintf(h, "%v|", v)
+		}
+		checksum ^= int64(h.Sum64())
+		n++
+		if n%ReconcileProgressEvery == 0 {
+			log.Printf("[OK] %s: checksum %s, %d filas procesadas", tableName, engine, n)
+		}
+	}
+	return checksum
+}
+
+// reconciliationStatus decide si una tabla pasa la reconciliacion. Un
+// mismatch dispara tanto por diferencia de row count fuera de tolerancia
+// como por diferencia de checksum con igual row count (el caso "Silencioso"
+// de valores truncados/corruptos sin perdida de filas). Separada de
+// runReconciliation para poder testear la comparacion sin una base de datos.
+func reconciliationStatus(srcRows, dstRows, srcHash, dstHash, tolerance int64) (status, reason string) {
+	delta := srcRows - dstRows
+	if delta < 0 {
+		delta = -delta
+	}
+
+	if delta > tolerance {
+		return "MISMATCH", fmt.Sprintf("row count: src_rows=%d dst_rows=%d", srcRows, dstRows)
+	}
+	if srcRows == dstRows && srcHash != dstHash {
+		return "MISMATCH", fmt.Sprintf("checksum: src_hash=%d dst_hash=%d (src_rows=%d dst_rows=%d)", srcHash, dstHash, srcRows, dstRows)
+	}
+	return "OK", ""
+}
+
+// runReconciliation compara row counts y checksums de columna para cada
+// tabla migrada contra la tolerancia configurada. El checksum implica un
+// segundo full scan completo de la tabla por motor (MSSQL y Postgres), asi
+// que por encima de reconcileChecksumThreshold (las mismas tablas grandes
+// que #4 chunkea, BD_FACTURA/declaracion) se omite y solo se compara row
+// count, para no duplicar el costo de carga en cada corrida/tick de cron.
+func runReconciliation(pg, ms *sql.DB, schema string, tables []string, runID string) int {
+	mismatches := 0
+	for _, table := range tables {
+		srcRows := getMSRowCount(ms, table)
+		dstRows := getPGRowCount(pg, schema, table)
+
+		var srcHash, dstHash int64
+		if *reconcileChecksumThreshold == 0 || srcRows <= *reconcileChecksumThreshold {
+			cols := getReconciliationColumns(ms, table)
+			srcHash = computeMSChecksum(ms, table, cols)
+			dstHash = computePGChecksum(pg, schema, table, cols)
+		} else {
+			log.Printf("[WARN] %s: %d filas supera reconcile-checksum-threshold (%d), se omite el checksum columna por columna y solo se compara row count", table, srcRows, *reconcileChecksumThreshold)
+		}
+
+		status, reason := reconciliationStatus(srcRows, dstRows, srcHash, dstHash, *reconcileTolerance)
+		if status == "MISMATCH" {
+			mismatches++
+			log.Printf("[ERROR] %s: reconciliacion MISMATCH (%s)", table, reason)
+			recordRunPhase(pg, runID, schema, table, "reconciled", dstRows, 0, "reconciliacion: "+reason)
+		}
+
+		pg.Exec(`INSERT INTO svcs_meta.reconciliation (run_id, source_db, table_name, src_rows, dst_rows, src_hash, dst_hash, status)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`, runID, schema, table, srcRows, dstRows, srcHash, dstHash, status)
+	}
+	return mismatches
 }
\ No newline at end of file
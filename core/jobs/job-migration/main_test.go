@@ -0,0 +1,176 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCoerceValue(t *testing.T) {
+	cases := []struct {
+		name      string
+		v         interface{}
+		mssqlType string
+		want      interface{}
+	}{
+		{"nil passthrough", nil, "INT", nil},
+		{"bit true", []byte{1}, "BIT", true},
+		{"bit false", []byte{0}, "BIT", false},
+		{"uniqueidentifier", []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}, "UNIQUEIDENTIFIER", "01020304-0506-0708-090a-0b0c0d0e0f10"},
+		{"binary passthrough", []byte{0xde, 0xad}, "VARBINARY", []byte{0xde, 0xad}},
+		{"string strips null bytes", "foo\x00bar", "VARCHAR", "foobar"},
+		{"bytes strips null bytes", []byte("foo\x00bar"), "VARCHAR", "foobar"},
+		{"bool passthrough", true, "BIT", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := coerceValue(c.v, c.mssqlType)
+			gotBytes, gotIsBytes := got.([]byte)
+			wantBytes, wantIsBytes := c.want.([]byte)
+			if gotIsBytes || wantIsBytes {
+				if string(gotBytes) != string(wantBytes) {
+					t.Fatalf("coerceValue(%v, %s) = %v, want %v", c.v, c.mssqlType, got, c.want)
+				}
+				return
+			}
+			if got != c.want {
+				t.Fatalf("coerceValue(%v, %s) = %v, want %v", c.v, c.mssqlType, got, c.want)
+			}
+		})
+	}
+}
+
+func TestIsUnsupportedCopyTypeErr(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil error", nil, false},
+		{"unsupported type", errString("unsupported type for COPY"), true},
+		{"copyin error", errString("pq: CopyIn failed"), true},
+		{"invalid byte sequence", errString("pq: invalid byte sequence for encoding \"UTF8\""), true},
+		{"unrelated error", errString("pq: relation does not exist"), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := isUnsupportedCopyTypeErr(c.err); got != c.want {
+				t.Fatalf("isUnsupportedCopyTypeErr(%v) = %v, want %v", c.err, got, c.want)
+			}
+		})
+	}
+}
+
+type errString string
+
+func (e errString) Error() string { return string(e) }
+
+func TestMysqlDialectMapType(t *testing.T) {
+	cases := []struct {
+		mssqlType        string
+		precision, scale int64
+		want             string
+	}{
+		{"TINYINT", 0, 0, "TINYINT UNSIGNED"},
+		{"BIGINT", 0, 0, "BIGINT"},
+		{"BIT", 0, 0, "BOOLEAN"},
+		{"DECIMAL", 10, 2, "DECIMAL(10,2)"},
+		{"DECIMAL", 0, 0, "DECIMAL(38,9)"},
+		{"NVARCHAR", 0, 0, "TEXT"},
+		{"VARBINARY", 0, 0, "BLOB"},
+		{"UNIQUEIDENTIFIER", 0, 0, "CHAR(36)"},
+		{"SOME_UNKNOWN_TYPE", 0, 0, "TEXT"},
+	}
+	d := mysqlDialect{}
+	for _, c := range cases {
+		t.Run(c.mssqlType, func(t *testing.T) {
+			if got := d.MapType(c.mssqlType, c.precision, c.scale); got != c.want {
+				t.Fatalf("MapType(%s, %d, %d) = %s, want %s", c.mssqlType, c.precision, c.scale, got, c.want)
+			}
+		})
+	}
+}
+
+func TestSqliteDialectMapType(t *testing.T) {
+	cases := []struct {
+		mssqlType string
+		want      string
+	}{
+		{"INT", "INTEGER"},
+		{"BIGINT", "INTEGER"},
+		{"BIT", "INTEGER"},
+		{"DECIMAL", "REAL"},
+		{"VARBINARY", "BLOB"},
+		{"NVARCHAR", "TEXT"},
+		{"UNIQUEIDENTIFIER", "TEXT"},
+	}
+	d := sqliteDialect{}
+	for _, c := range cases {
+		t.Run(c.mssqlType, func(t *testing.T) {
+			if got := d.MapType(c.mssqlType, 0, 0); got != c.want {
+				t.Fatalf("MapType(%s) = %s, want %s", c.mssqlType, got, c.want)
+			}
+		})
+	}
+}
+
+func TestBuildChunkRanges(t *testing.T) {
+	t.Run("empty input yields no ranges", func(t *testing.T) {
+		if got := buildChunkRanges(nil); len(got) != 0 {
+			t.Fatalf("buildChunkRanges(nil) = %v, want empty", got)
+		}
+	})
+
+	t.Run("single group is fully open", func(t *testing.T) {
+		got := buildChunkRanges([]interface{}{int64(1)})
+		if len(got) != 1 || got[0].Lo != int64(1) || got[0].HasHi {
+			t.Fatalf("buildChunkRanges([1]) = %+v, want one open range starting at 1", got)
+		}
+	})
+
+	t.Run("last group stays open, others close at the next lo", func(t *testing.T) {
+		los := []interface{}{int64(1), int64(100), int64(250)}
+		got := buildChunkRanges(los)
+		if len(got) != 3 {
+			t.Fatalf("len(buildChunkRanges(%v)) = %d, want 3", los, len(got))
+		}
+		if got[0].Lo != int64(1) || !got[0].HasHi || got[0].Hi != int64(100) {
+			t.Fatalf("range 0 = %+v, want Lo=1 Hi=100 HasHi=true", got[0])
+		}
+		if got[1].Lo != int64(100) || !got[1].HasHi || got[1].Hi != int64(250) {
+			t.Fatalf("range 1 = %+v, want Lo=100 Hi=250 HasHi=true", got[1])
+		}
+		if got[2].Lo != int64(250) || got[2].HasHi {
+			t.Fatalf("range 2 = %+v, want Lo=250 HasHi=false (open, catches rows inserted after the scan)", got[2])
+		}
+	})
+}
+
+func TestReconciliationStatus(t *testing.T) {
+	cases := []struct {
+		name                        string
+		srcRows, dstRows            int64
+		srcHash, dstHash, tolerance int64
+		wantStatus                  string
+		wantReasonPrefix            string
+	}{
+		{"rows and hash match", 100, 100, 42, 42, 0, "OK", ""},
+		{"row count within tolerance", 100, 98, 42, 42, 5, "OK", ""},
+		{"row count outside tolerance", 100, 90, 42, 42, 5, "MISMATCH", "row count"},
+		{"same row count, hash differs", 100, 100, 42, 7, 0, "MISMATCH", "checksum"},
+		{"row delta wins over hash check", 100, 80, 42, 7, 5, "MISMATCH", "row count"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			status, reason := reconciliationStatus(c.srcRows, c.dstRows, c.srcHash, c.dstHash, c.tolerance)
+			if status != c.wantStatus {
+				t.Fatalf("reconciliationStatus(...) = %s (%s), want %s", status, reason, c.wantStatus)
+			}
+			if status == "MISMATCH" && reason == "" {
+				t.Fatalf("expected a non-empty reason when status is MISMATCH")
+			}
+			if !strings.HasPrefix(reason, c.wantReasonPrefix) {
+				t.Fatalf("reconciliationStatus(...) reason = %q, want prefix %q", reason, c.wantReasonPrefix)
+			}
+		})
+	}
+}